@@ -0,0 +1,276 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package jobs persists long-running background tasks (index builds, createIndexes with
+// background: true, collMod, TTL sweeps, compact) in a PostgreSQL-backed queue, so that
+// FerretDB can honor MongoDB's async semantics for them instead of blocking the client for
+// the full operation.
+//
+// Dispatch uses row-level `SELECT ... FOR UPDATE SKIP LOCKED` so that multiple FerretDB
+// instances sharing the same PostgreSQL database can run workers without double-processing
+// a job.
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+
+	"github.com/FerretDB/FerretDB/internal/pg"
+)
+
+// Status is the lifecycle state of a Job.
+type Status string
+
+// Job statuses.
+const (
+	StatusPending   Status = "pending"
+	StatusRunning   Status = "running"
+	StatusDone      Status = "done"
+	StatusFailed    Status = "failed"
+	StatusCancelled Status = "cancelled"
+)
+
+// Job is a single unit of background work.
+type Job struct {
+	ID          int64
+	Type        string
+	Payload     []byte // JSON-encoded, handler-specific
+	Status      Status
+	Attempts    int
+	MaxAttempts int
+	LastError   string
+	RunAt       time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// ddl creates the table Queue relies on. It is run by Queue.EnsureSchema; FerretDB has no
+// migration framework yet, so callers are expected to run it once at startup.
+const ddl = `
+CREATE TABLE IF NOT EXISTS ferretdb_jobs (
+	id           BIGSERIAL PRIMARY KEY,
+	type         TEXT NOT NULL,
+	payload      JSONB NOT NULL DEFAULT '{}',
+	status       TEXT NOT NULL DEFAULT 'pending',
+	attempts     INTEGER NOT NULL DEFAULT 0,
+	max_attempts INTEGER NOT NULL DEFAULT 5,
+	last_error   TEXT NOT NULL DEFAULT '',
+	run_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+	created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+	updated_at   TIMESTAMPTZ NOT NULL DEFAULT now()
+)`
+
+// Queue persists and dispatches Jobs on top of a pg.Pool.
+type Queue struct {
+	pool *pg.Pool
+}
+
+// NewQueue returns a Queue backed by pool.
+func NewQueue(pool *pg.Pool) *Queue {
+	return &Queue{pool: pool}
+}
+
+// EnsureSchema creates the backing table if it does not already exist.
+func (q *Queue) EnsureSchema(ctx context.Context) error {
+	if _, err := q.pool.Exec(ctx, ddl); err != nil {
+		return fmt.Errorf("jobs.Queue.EnsureSchema: %w", err)
+	}
+
+	return nil
+}
+
+// Enqueue persists job and returns the ID it was assigned. MaxAttempts defaults to 5 if unset.
+func (q *Queue) Enqueue(ctx context.Context, job Job) (int64, error) {
+	if job.MaxAttempts == 0 {
+		job.MaxAttempts = 5
+	}
+
+	if job.Payload == nil {
+		job.Payload = []byte("{}")
+	}
+
+	row := q.pool.QueryRow(ctx, `
+		INSERT INTO ferretdb_jobs (type, payload, max_attempts)
+		VALUES ($1, $2, $3)
+		RETURNING id`,
+		job.Type, job.Payload, job.MaxAttempts,
+	)
+
+	var id int64
+	if err := row.Scan(&id); err != nil {
+		return 0, fmt.Errorf("jobs.Queue.Enqueue: %w", err)
+	}
+
+	return id, nil
+}
+
+// CurrentOp returns the jobs currently dispatched to a worker, mirroring MongoDB's
+// `currentOp` for background FerretDB operations.
+func (q *Queue) CurrentOp(ctx context.Context) ([]Job, error) {
+	rows, err := q.pool.Query(ctx, `
+		SELECT id, type, payload, status, attempts, max_attempts, last_error, run_at, created_at, updated_at
+		FROM ferretdb_jobs
+		WHERE status = $1
+		ORDER BY id`,
+		StatusRunning,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("jobs.Queue.CurrentOp: %w", err)
+	}
+	defer rows.Close()
+
+	var res []Job
+
+	for rows.Next() {
+		var j Job
+		if err := rows.Scan(&j.ID, &j.Type, &j.Payload, &j.Status, &j.Attempts, &j.MaxAttempts,
+			&j.LastError, &j.RunAt, &j.CreatedAt, &j.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("jobs.Queue.CurrentOp: %w", err)
+		}
+
+		res = append(res, j)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("jobs.Queue.CurrentOp: %w", err)
+	}
+
+	return res, nil
+}
+
+// KillOp requests cancellation of the job with the given ID, mirroring MongoDB's `killOp`.
+// A job already being worked on is not interrupted: its handler runs to completion, and
+// finish then leaves the cancelled status in place instead of marking the job done, failed
+// or pending for a retry.
+func (q *Queue) KillOp(ctx context.Context, id int64) error {
+	tag, err := q.pool.Exec(ctx, `
+		UPDATE ferretdb_jobs
+		SET status = $1, updated_at = now()
+		WHERE id = $2 AND status IN ($3, $4)`,
+		StatusCancelled, id, StatusPending, StatusRunning,
+	)
+	if err != nil {
+		return fmt.Errorf("jobs.Queue.KillOp: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("jobs.Queue.KillOp: job %d not found or already finished", id)
+	}
+
+	return nil
+}
+
+// dequeue claims the oldest due pending job for processing, using SELECT ... FOR UPDATE
+// SKIP LOCKED so concurrent workers never pick up the same row. It returns (nil, nil) if
+// no job is due.
+func (q *Queue) dequeue(ctx context.Context) (*Job, error) {
+	var j Job
+
+	err := q.pool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		row := tx.QueryRow(ctx, `
+			SELECT id, type, payload, status, attempts, max_attempts, last_error, run_at, created_at, updated_at
+			FROM ferretdb_jobs
+			WHERE status = $1 AND run_at <= now()
+			ORDER BY id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED`,
+			StatusPending,
+		)
+
+		if err := row.Scan(&j.ID, &j.Type, &j.Payload, &j.Status, &j.Attempts, &j.MaxAttempts,
+			&j.LastError, &j.RunAt, &j.CreatedAt, &j.UpdatedAt); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				j.ID = 0
+				return nil
+			}
+
+			return err
+		}
+
+		_, err := tx.Exec(ctx, `
+			UPDATE ferretdb_jobs
+			SET status = $1, attempts = attempts + 1, updated_at = now()
+			WHERE id = $2`,
+			StatusRunning, j.ID,
+		)
+
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("jobs.Queue.dequeue: %w", err)
+	}
+
+	if j.ID == 0 {
+		return nil, nil
+	}
+
+	j.Status = StatusRunning
+	j.Attempts++
+
+	return &j, nil
+}
+
+// finish records the outcome of processing job. Each UPDATE is guarded by
+// `WHERE status = running`, so a job KillOp cancelled while it was being worked on stays
+// cancelled instead of being resurrected as done/failed/pending once the handler returns.
+func (q *Queue) finish(ctx context.Context, job *Job, runErr error) error {
+	if runErr == nil {
+		_, err := q.pool.Exec(ctx, `
+			UPDATE ferretdb_jobs SET status = $1, last_error = '', updated_at = now()
+			WHERE id = $2 AND status = $3`,
+			StatusDone, job.ID, StatusRunning,
+		)
+
+		return err
+	}
+
+	if job.Attempts >= job.MaxAttempts {
+		_, err := q.pool.Exec(ctx, `
+			UPDATE ferretdb_jobs SET status = $1, last_error = $2, updated_at = now()
+			WHERE id = $3 AND status = $4`,
+			StatusFailed, runErr.Error(), job.ID, StatusRunning,
+		)
+
+		return err
+	}
+
+	_, err := q.pool.Exec(ctx, `
+		UPDATE ferretdb_jobs
+		SET status = $1, last_error = $2, run_at = $3, updated_at = now()
+		WHERE id = $4 AND status = $5`,
+		StatusPending, runErr.Error(), time.Now().Add(backoff(job.Attempts)), job.ID, StatusRunning,
+	)
+
+	return err
+}
+
+// backoff returns the retry delay for the given attempt count, doubling from 1s and capped
+// at 5 minutes.
+func backoff(attempt int) time.Duration {
+	const (
+		base = time.Second
+		max  = 5 * time.Minute
+	)
+
+	d := base << attempt
+	if d <= 0 || d > max {
+		return max
+	}
+
+	return d
+}
@@ -0,0 +1,141 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobs
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/FerretDB/FerretDB/internal/pg"
+)
+
+func TestBackoff(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, time.Second, backoff(0))
+	assert.Equal(t, 2*time.Second, backoff(1))
+	assert.Equal(t, 4*time.Second, backoff(2))
+	assert.Equal(t, 5*time.Minute, backoff(30), "backoff must cap instead of overflowing")
+}
+
+// testQueue connects to the PostgreSQL instance named by FERRETDB_POSTGRESQL_TEST_URL and
+// returns a Queue with a freshly (re)created backing table. It skips the test if that
+// environment variable is unset, since this package has no way to start PostgreSQL itself.
+func testQueue(t *testing.T) *Queue {
+	t.Helper()
+
+	dsn := os.Getenv("FERRETDB_POSTGRESQL_TEST_URL")
+	if dsn == "" {
+		t.Skip("FERRETDB_POSTGRESQL_TEST_URL is not set, skipping test that needs PostgreSQL")
+	}
+
+	ctx := context.Background()
+
+	pool, err := pg.NewPool(dsn, zaptest.NewLogger(t), false)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	_, err = pool.Exec(ctx, "DROP TABLE IF EXISTS ferretdb_jobs")
+	require.NoError(t, err)
+
+	q := NewQueue(pool)
+	require.NoError(t, q.EnsureSchema(ctx))
+
+	return q
+}
+
+func TestQueueEnqueueDequeue(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	q := testQueue(t)
+
+	id, err := q.Enqueue(ctx, Job{Type: "index-build"})
+	require.NoError(t, err)
+	assert.NotZero(t, id)
+
+	job, err := q.dequeue(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, job)
+	assert.Equal(t, id, job.ID)
+	assert.Equal(t, StatusRunning, job.Status)
+	assert.Equal(t, 1, job.Attempts)
+
+	second, err := q.dequeue(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, second, "the same job must not be dequeued twice")
+}
+
+func TestQueueKillOpPreventsResurrection(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	q := testQueue(t)
+
+	id, err := q.Enqueue(ctx, Job{Type: "ttl-sweep"})
+	require.NoError(t, err)
+
+	job, err := q.dequeue(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, job)
+
+	require.NoError(t, q.KillOp(ctx, id))
+
+	// finish() must not overwrite the cancellation, even though the handler "succeeded".
+	require.NoError(t, q.finish(ctx, job, nil))
+
+	ops, err := q.CurrentOp(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, ops)
+
+	var status Status
+	require.NoError(t, q.pool.QueryRow(ctx, "SELECT status FROM ferretdb_jobs WHERE id = $1", id).Scan(&status))
+	assert.Equal(t, StatusCancelled, status)
+}
+
+func TestWorkerProcessesJob(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	q := testQueue(t)
+
+	_, err := q.Enqueue(ctx, Job{Type: "compact"})
+	require.NoError(t, err)
+
+	processed := make(chan struct{}, 1)
+
+	w := NewWorker(q, zap.NewNop(), 10*time.Millisecond)
+	w.Handle("compact", func(ctx context.Context, job *Job) error {
+		processed <- struct{}{}
+		return nil
+	})
+
+	go w.Run(ctx) //nolint:errcheck // ctx cancellation is the expected way this returns
+
+	select {
+	case <-processed:
+	case <-ctx.Done():
+		t.Fatal("job was not processed before the deadline")
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Handler processes a single Job. A returned error causes the job to be retried (with
+// exponential backoff) until it runs out of attempts, at which point it is marked failed.
+type Handler func(ctx context.Context, job *Job) error
+
+// Worker polls a Queue and dispatches due jobs to registered Handlers by Job.Type.
+type Worker struct {
+	queue        *Queue
+	logger       *zap.Logger
+	handlers     map[string]Handler
+	pollInterval time.Duration
+}
+
+// NewWorker returns a Worker that polls queue every pollInterval for due jobs.
+func NewWorker(queue *Queue, logger *zap.Logger, pollInterval time.Duration) *Worker {
+	if pollInterval <= 0 {
+		pollInterval = time.Second
+	}
+
+	return &Worker{
+		queue:        queue,
+		logger:       logger,
+		handlers:     make(map[string]Handler),
+		pollInterval: pollInterval,
+	}
+}
+
+// Handle registers handler for the given job type. It is not safe to call Handle once Run
+// has started.
+func (w *Worker) Handle(jobType string, handler Handler) {
+	w.handlers[jobType] = handler
+}
+
+// Run polls for due jobs and dispatches them to the registered Handlers until ctx is
+// cancelled, at which point it returns ctx.Err().
+func (w *Worker) Run(ctx context.Context) error {
+	ticker := time.NewTicker(w.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			w.runOnce(ctx)
+		}
+	}
+}
+
+// runOnce dequeues and processes as many due jobs as are currently available.
+func (w *Worker) runOnce(ctx context.Context) {
+	for {
+		job, err := w.queue.dequeue(ctx)
+		if err != nil {
+			w.logger.Error("jobs.Worker: dequeue failed", zap.Error(err))
+			return
+		}
+
+		if job == nil {
+			return
+		}
+
+		w.process(ctx, job)
+	}
+}
+
+func (w *Worker) process(ctx context.Context, job *Job) {
+	handler, ok := w.handlers[job.Type]
+	if !ok {
+		_ = w.queue.finish(ctx, job, fmt.Errorf("jobs.Worker: no handler registered for type %q", job.Type))
+		return
+	}
+
+	err := handler(ctx, job)
+
+	if finishErr := w.queue.finish(ctx, job, err); finishErr != nil {
+		w.logger.Error("jobs.Worker: failed to record job outcome", zap.Int64("id", job.ID), zap.Error(finishErr))
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pg
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// Backend is a handle to a SQL engine that FerretDB can store data in.
+//
+// Pool (backed by PostgreSQL via pgx) is the only implementation registered so far; this is
+// step one of a larger change.
+//
+// TODO: adding SQLite and a second Postgres-compatible driver behind this same interface is
+// the part of this work that actually lets FerretDB run without a standalone PostgreSQL
+// server - that is NOT done by this package yet, and no other driver exists anywhere in this
+// series. Do not treat the backlog item this came from as resolved until a real second
+// driver is registered here.
+type Backend interface {
+	// Close releases all resources held by the backend.
+	Close()
+}
+
+// OpenFunc opens a Backend for a DSN whose scheme was used to look it up in the registry.
+type OpenFunc func(ctx context.Context, dsn string, lazy bool, logger *zap.Logger) (Backend, error)
+
+// dialectValidator checks that the settings a backend connected with are ones FerretDB supports,
+// replacing the single hardcoded PostgreSQL encoding/locale check with a per-dialect one.
+type dialectValidator interface {
+	validateSettings(ctx context.Context) error
+}
+
+var _ dialectValidator = (*Pool)(nil)
+
+// HealthChecker is implemented by backends that can report whether they are reachable; Pool
+// implements it with Ping. Pool.Health is not part of the interface. No handler uses
+// HealthChecker: neither `serverStatus` nor the debug listener reports backend health.
+type HealthChecker interface {
+	Ping(ctx context.Context) error
+}
+
+var _ HealthChecker = (*Pool)(nil)
+
+var (
+	backendsMu sync.Mutex
+	backends   = map[string]OpenFunc{}
+)
+
+// Register makes a backend available under the given DSN scheme (e.g. "postgres", "sqlite").
+// It panics if Register is called twice for the same scheme, or if open is nil.
+func Register(scheme string, open OpenFunc) {
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+
+	if open == nil {
+		panic("pg: Register open func is nil")
+	}
+
+	if _, dup := backends[scheme]; dup {
+		panic("pg: Register called twice for scheme " + scheme)
+	}
+
+	backends[scheme] = open
+}
+
+// Open parses dsn, looks up the backend registered for its scheme, and opens it.
+//
+// Unlike NewPool, which always connects via the PostgreSQL driver, Open lets the scheme in dsn
+// pick the driver, so that future non-PostgreSQL backends can be selected without changing
+// call sites.
+func Open(ctx context.Context, dsn string, lazy bool, logger *zap.Logger) (Backend, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("pg.Open: %w", err)
+	}
+
+	scheme := u.Scheme
+	if scheme == "" {
+		scheme = "postgres"
+	}
+
+	backendsMu.Lock()
+	open, ok := backends[scheme]
+	backendsMu.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("pg.Open: no backend registered for scheme %q", scheme)
+	}
+
+	return open(ctx, dsn, lazy, logger)
+}
+
+func init() {
+	// NewPoolWithConfig, not NewPool, so that a ctx with a deadline/cancellation passed to
+	// Open actually bounds the connection attempt instead of being silently dropped.
+	open := func(ctx context.Context, dsn string, lazy bool, logger *zap.Logger) (Backend, error) {
+		return NewPoolWithConfig(ctx, dsn, nil, logger, lazy)
+	}
+
+	Register("postgres", open)
+	Register("postgresql", open)
+}
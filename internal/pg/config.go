@@ -0,0 +1,198 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pg
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"go.uber.org/zap"
+)
+
+// PoolConfig holds the pool-sizing, TLS, and authentication knobs that NewPool previously
+// hid behind a bare connection string, so that operators can set them without editing code.
+// A zero PoolConfig keeps pgx's own defaults.
+type PoolConfig struct {
+	// MinConns and MaxConns bound the pgxpool connection pool size.
+	// Zero means "use pgxpool's default" (MinConns 0, MaxConns the greater of 4 and runtime.NumCPU()).
+	MinConns int32
+	MaxConns int32
+
+	// MaxConnLifetime and MaxConnIdleTime bound how long a pooled connection may live and sit idle.
+	// Zero means "use pgxpool's default".
+	MaxConnLifetime time.Duration
+	MaxConnIdleTime time.Duration
+
+	// HealthCheckPeriod controls how often pgxpool checks idle connections.
+	// Zero means "use pgxpool's default".
+	HealthCheckPeriod time.Duration
+
+	// Username and Password, when set, override the credentials from the connection string.
+	// PostgreSQL negotiates SCRAM-SHA-256 automatically for them when the server requires it.
+	Username string
+	Password string
+
+	// TLS, when set, is used as-is for the connection. TLSCertFile/TLSKeyFile/TLSRootCAFile are
+	// a convenience for the common case of a client certificate plus a custom CA; they are
+	// ignored if TLS is already set.
+	TLS           *tls.Config
+	TLSCertFile   string
+	TLSKeyFile    string
+	TLSRootCAFile string
+
+	// PreferSimpleProtocol disables prepared statements, trading performance for compatibility
+	// with connection poolers (e.g. PgBouncer in transaction mode) that can't track them.
+	PreferSimpleProtocol bool
+
+	// DisableStatementCache disables pgx's prepared-statement cache
+	// (the "statement_cache_mode=disable" equivalent).
+	DisableStatementCache bool
+
+	// UnixSocketDir, when set, connects via the PostgreSQL Unix domain socket in that directory
+	// instead of TCP, overriding any host in the connection string. This is equivalent to a DSN
+	// with `host=/path/to/dir`, but is validated upfront: the directory and its
+	// `.s.PGSQL.<port>` socket file are checked to exist before ConnectConfig is attempted.
+	UnixSocketDir string
+}
+
+// NewPoolWithConfig is like NewPool but accepts a PoolConfig for the pool-sizing, TLS, and
+// authentication options pgxpool.Config exposes that NewPool's connString-and-lazy signature
+// does not. A nil cfg behaves exactly like NewPool.
+func NewPoolWithConfig(ctx context.Context, connString string, cfg *PoolConfig, logger *zap.Logger, lazy bool) (*Pool, error) {
+	config, err := pgxpool.ParseConfig(connString)
+	if err != nil {
+		return nil, fmt.Errorf("pg.NewPoolWithConfig: %w", err)
+	}
+
+	if err := applyPoolConfig(config, cfg); err != nil {
+		return nil, fmt.Errorf("pg.NewPoolWithConfig: %w", err)
+	}
+
+	applyBaseRuntimeParams(config, logger)
+
+	res, err := newPoolFromConfig(ctx, config, logger, lazy)
+	if err != nil {
+		return nil, fmt.Errorf("pg.NewPoolWithConfig: %w", err)
+	}
+
+	return res, nil
+}
+
+// applyPoolConfig overlays the non-zero fields of cfg onto config. A nil cfg leaves config
+// unchanged.
+func applyPoolConfig(config *pgxpool.Config, cfg *PoolConfig) error {
+	if cfg == nil {
+		return nil
+	}
+
+	if cfg.MinConns != 0 {
+		config.MinConns = cfg.MinConns
+	}
+
+	if cfg.MaxConns != 0 {
+		config.MaxConns = cfg.MaxConns
+	}
+
+	if cfg.MaxConnLifetime != 0 {
+		config.MaxConnLifetime = cfg.MaxConnLifetime
+	}
+
+	if cfg.MaxConnIdleTime != 0 {
+		config.MaxConnIdleTime = cfg.MaxConnIdleTime
+	}
+
+	if cfg.HealthCheckPeriod != 0 {
+		config.HealthCheckPeriod = cfg.HealthCheckPeriod
+	}
+
+	if cfg.Username != "" {
+		config.ConnConfig.User = cfg.Username
+	}
+
+	if cfg.Password != "" {
+		config.ConnConfig.Password = cfg.Password
+	}
+
+	config.ConnConfig.PreferSimpleProtocol = cfg.PreferSimpleProtocol
+
+	if cfg.DisableStatementCache {
+		config.ConnConfig.BuildStatementCache = nil
+	}
+
+	tlsConfig := cfg.TLS
+
+	if tlsConfig == nil && (cfg.TLSCertFile != "" || cfg.TLSKeyFile != "" || cfg.TLSRootCAFile != "") {
+		var err error
+
+		tlsConfig, err = loadTLSConfig(cfg.TLSCertFile, cfg.TLSKeyFile, cfg.TLSRootCAFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	if tlsConfig != nil {
+		config.ConnConfig.TLSConfig = tlsConfig
+	}
+
+	if cfg.UnixSocketDir != "" {
+		port := config.ConnConfig.Port
+
+		if err := validateUnixSocketDir(cfg.UnixSocketDir, port); err != nil {
+			return err
+		}
+
+		config.ConnConfig.Host = cfg.UnixSocketDir
+		config.ConnConfig.TLSConfig = nil
+	}
+
+	return nil
+}
+
+// loadTLSConfig builds a *tls.Config from a client certificate/key pair and a root CA file.
+// certFile and keyFile must be given together; the pair or rootCAFile may be empty, in which
+// case that part is left unset.
+func loadTLSConfig(certFile, keyFile, rootCAFile string) (*tls.Config, error) {
+	tlsConfig := new(tls.Config)
+
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("pg.loadTLSConfig: %w", err)
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if rootCAFile != "" {
+		pem, err := os.ReadFile(rootCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("pg.loadTLSConfig: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("pg.loadTLSConfig: no certificates found in %s", rootCAFile)
+		}
+
+		tlsConfig.RootCAs = pool
+	}
+
+	return tlsConfig, nil
+}
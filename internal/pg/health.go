@@ -0,0 +1,71 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pg
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// HealthReport describes the state of a Pool at the time Health was called.
+type HealthReport struct {
+	// PoolStat is the pgxpool connection pool statistics (acquired/idle conns, wait counts, etc).
+	PoolStat *pgxpool.Stat
+
+	// ServerVersion is the value of PostgreSQL's `server_version` setting.
+	ServerVersion string
+
+	// InRecovery is true if the server is a replica currently replaying WAL
+	// (the result of `pg_is_in_recovery()`).
+	InRecovery bool
+
+	// SettingsValid is the result of the same encoding/locale validation NewPool runs at startup,
+	// re-checked so that orchestration systems can detect a server reconfigured after connection.
+	SettingsValid bool
+}
+
+// Ping checks that the pool can still reach the PostgreSQL server.
+func (p *Pool) Ping(ctx context.Context) error {
+	if err := p.Pool.Ping(ctx); err != nil {
+		return fmt.Errorf("pg.Pool.Ping: %w", err)
+	}
+
+	return nil
+}
+
+// Health returns a HealthReport describing the current state of the pool and the PostgreSQL
+// server it is connected to. It is meant to back both `serverStatus` and liveness/readiness
+// probes, so it deliberately does not return an error for a degraded-but-reachable server;
+// callers should inspect the report's fields.
+func (p *Pool) Health(ctx context.Context) (*HealthReport, error) {
+	if err := p.Ping(ctx); err != nil {
+		return nil, err
+	}
+
+	report := &HealthReport{
+		PoolStat: p.Pool.Stat(),
+	}
+
+	row := p.QueryRow(ctx, "SELECT current_setting('server_version'), pg_is_in_recovery()")
+	if err := row.Scan(&report.ServerVersion, &report.InRecovery); err != nil {
+		return nil, fmt.Errorf("pg.Pool.Health: %w", err)
+	}
+
+	report.SettingsValid = p.validateSettings(ctx) == nil
+
+	return report, nil
+}
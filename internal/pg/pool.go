@@ -26,10 +26,11 @@ import (
 )
 
 const (
-	// Supported encoding:
+	// Supported encoding for the PostgreSQL dialect:
 	encUTF8 = "UTF8"
 
-	// Supported locales: (For more info see: https://www.gnu.org/software/libc/manual/html_node/Standard-Locales.html)
+	// Supported locales for the PostgreSQL dialect: (For more info see:
+	// https://www.gnu.org/software/libc/manual/html_node/Standard-Locales.html)
 	localeC     = "C"
 	localePOSIX = "POSIX"
 )
@@ -46,8 +47,19 @@ func NewPool(connString string, logger *zap.Logger, lazy bool) (*Pool, error) {
 		return nil, fmt.Errorf("pg.NewPool: %w", err)
 	}
 
-	config.LazyConnect = lazy
+	applyBaseRuntimeParams(config, logger)
+
+	res, err := newPoolFromConfig(context.Background(), config, logger, lazy)
+	if err != nil {
+		return nil, fmt.Errorf("pg.NewPool: %w", err)
+	}
+
+	return res, nil
+}
 
+// applyBaseRuntimeParams sets the runtime parameters and logger every pool needs regardless of
+// which constructor built its pgxpool.Config.
+func applyBaseRuntimeParams(config *pgxpool.Config, logger *zap.Logger) {
 	// That only affects text protocol; pgx mostly uses a binary one.
 	// See:
 	// * https://github.com/jackc/pgx/issues/520
@@ -63,12 +75,16 @@ func NewPool(connString string, logger *zap.Logger, lazy bool) (*Pool, error) {
 		config.ConnConfig.LogLevel = pgx.LogLevelTrace
 		config.ConnConfig.Logger = zapadapter.NewLogger(logger.Named("pgconn.Pool"))
 	}
+}
 
-	ctx := context.Background()
+// newPoolFromConfig connects a pgxpool.Config built by NewPool or NewPoolWithConfig and,
+// unless lazy, validates the resulting connection's settings.
+func newPoolFromConfig(ctx context.Context, config *pgxpool.Config, logger *zap.Logger, lazy bool) (*Pool, error) {
+	config.LazyConnect = lazy
 
 	p, err := pgxpool.ConnectConfig(ctx, config)
 	if err != nil {
-		return nil, fmt.Errorf("pg.NewPool: %w", err)
+		return nil, err
 	}
 
 	res := &Pool{
@@ -76,43 +92,51 @@ func NewPool(connString string, logger *zap.Logger, lazy bool) (*Pool, error) {
 	}
 
 	if !lazy {
-		err = res.checkConnection(ctx)
+		err = res.validateSettings(ctx)
 	}
 
 	return res, err
 }
 
-func (p *Pool) checkConnection(ctx context.Context) error {
+// Close releases all resources held by the pool, satisfying the Backend interface.
+func (p *Pool) Close() {
+	p.Pool.Close()
+}
+
+// validateSettings checks that the PostgreSQL server Pool is connected to runs with the
+// encoding and locale settings FerretDB requires. It satisfies the dialectValidator interface,
+// replacing what used to be a single hardcoded checkConnection.
+func (p *Pool) validateSettings(ctx context.Context) error {
 	logger := p.Config().ConnConfig.Logger
 
 	rows, err := p.Query(ctx, "SHOW ALL")
 	if err != nil {
-		return fmt.Errorf("pg.Pool.checkConnection: %w", err)
+		return fmt.Errorf("pg.Pool.validateSettings: %w", err)
 	}
 	defer rows.Close()
 
 	for rows.Next() {
 		var name, setting, description string
 		if err := rows.Scan(&name, &setting, &description); err != nil {
-			return fmt.Errorf("pg.Pool.checkConnection: %w", err)
+			return fmt.Errorf("pg.Pool.validateSettings: %w", err)
 		}
 
 		switch name {
 		case "server_encoding":
 			if setting != encUTF8 {
-				return fmt.Errorf("pg.Pool.checkConnection: %q is %q, want %q", name, setting, encUTF8)
+				return fmt.Errorf("pg.Pool.validateSettings: %q is %q, want %q", name, setting, encUTF8)
 			}
 		case "client_encoding":
 			if setting != encUTF8 {
-				return fmt.Errorf("pg.Pool.checkConnection: %q is %q, want %q", name, setting, encUTF8)
+				return fmt.Errorf("pg.Pool.validateSettings: %q is %q, want %q", name, setting, encUTF8)
 			}
 		case "lc_collate":
 			if setting != localeC && setting != localePOSIX && !validUtf8Locale(setting) {
-				return fmt.Errorf("pg.Pool.checkConnection: %q is %q", name, setting)
+				return fmt.Errorf("pg.Pool.validateSettings: %q is %q", name, setting)
 			}
 		case "lc_ctype":
 			if setting != localeC && setting != localePOSIX && !validUtf8Locale(setting) {
-				return fmt.Errorf("pg.Pool.checkConnection: %q is %q", name, setting)
+				return fmt.Errorf("pg.Pool.validateSettings: %q is %q", name, setting)
 			}
 		default:
 			continue
@@ -127,7 +151,7 @@ func (p *Pool) checkConnection(ctx context.Context) error {
 	}
 
 	if err := rows.Err(); err != nil {
-		return fmt.Errorf("pg.Pool.checkConnection: %w", err)
+		return fmt.Errorf("pg.Pool.validateSettings: %w", err)
 	}
 
 	return nil
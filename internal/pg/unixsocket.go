@@ -0,0 +1,33 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pg
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// defaultPort is used to resolve the socket filename when the DSN does not specify a port.
+const defaultPort = 5432
+
+// socketPath returns the `.s.PGSQL.<port>` file PostgreSQL listens on inside dir, matching the
+// naming Postgres itself uses for its Unix domain socket.
+func socketPath(dir string, port uint16) string {
+	if port == 0 {
+		port = defaultPort
+	}
+
+	return filepath.Join(dir, fmt.Sprintf(".s.PGSQL.%d", port))
+}
@@ -0,0 +1,65 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !windows
+// +build !windows
+
+package pg
+
+import (
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSocketPath(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, filepath.Join("/var/run/postgresql", ".s.PGSQL.5432"), socketPath("/var/run/postgresql", 5432))
+	assert.Equal(t, filepath.Join("/tmp", ".s.PGSQL.5432"), socketPath("/tmp", 0), "port 0 should resolve to the default port")
+}
+
+func TestValidateUnixSocketDir(t *testing.T) {
+	t.Parallel()
+
+	t.Run("missing directory", func(t *testing.T) {
+		t.Parallel()
+
+		err := validateUnixSocketDir(filepath.Join(t.TempDir(), "does-not-exist"), 5432)
+		require.Error(t, err)
+	})
+
+	t.Run("directory without socket file", func(t *testing.T) {
+		t.Parallel()
+
+		err := validateUnixSocketDir(t.TempDir(), 5432)
+		require.Error(t, err)
+	})
+
+	t.Run("valid socket", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		path := socketPath(dir, 5432)
+
+		l, err := net.Listen("unix", path)
+		require.NoError(t, err)
+		defer l.Close()
+
+		assert.NoError(t, validateUnixSocketDir(dir, 5432))
+	})
+}
@@ -0,0 +1,50 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !windows
+// +build !windows
+
+package pg
+
+import (
+	"fmt"
+	"os"
+)
+
+// validateUnixSocketDir checks that dir exists and that the PostgreSQL socket file PoolConfig
+// expects to find inside it is actually a Unix domain socket, so that a misconfigured
+// UnixSocketDir fails fast at startup instead of as an opaque dial error.
+func validateUnixSocketDir(dir string, port uint16) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("pg: unix socket directory %s: %w", dir, err)
+	}
+
+	if !info.IsDir() {
+		return fmt.Errorf("pg: unix socket directory %s is not a directory", dir)
+	}
+
+	path := socketPath(dir, port)
+
+	sockInfo, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("pg: unix socket %s: %w", path, err)
+	}
+
+	if sockInfo.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("pg: %s is not a Unix domain socket", path)
+	}
+
+	return nil
+}
@@ -0,0 +1,27 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+// +build windows
+
+package pg
+
+import "fmt"
+
+// validateUnixSocketDir always fails: PostgreSQL does not listen on Unix domain sockets on
+// Windows, so PoolConfig.UnixSocketDir is rejected there instead of producing a confusing
+// dial error later.
+func validateUnixSocketDir(dir string, port uint16) error {
+	return fmt.Errorf("pg: UnixSocketDir is not supported on Windows")
+}